@@ -0,0 +1,102 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package beater
+
+import (
+	"fmt"
+	"os"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/paths"
+
+	"github.com/elastic/beats/v7/filebeat/input/filestream"
+	"github.com/elastic/beats/v7/libbeat/beat"
+)
+
+// Filebeat is this beat's beat.Beater implementation.
+type Filebeat struct {
+	rawConfig *conf.C
+}
+
+// New creates a new Filebeat from the raw top-level config. It satisfies
+// beat.Creator and is the function root.go hands to the root command.
+func New(b *beat.Beat, rawConfig *conf.C) (beat.Beater, error) {
+	return &Filebeat{rawConfig: rawConfig}, nil
+}
+
+// rawFilebeatConfig is the subset of the top-level config Run needs before
+// any input is created.
+type rawFilebeatConfig struct {
+	Inputs     []*conf.C             `config:"filebeat.inputs"`
+	AutoIDMode filestream.AutoIDMode `config:"filebeat.input_auto_id"`
+}
+
+// Run validates every configured filestream input before anything else: a
+// misconfigured input must stop Filebeat from starting at all, never leave
+// it partially started with some inputs silently missing.
+func (fb *Filebeat) Run(b *beat.Beat) error {
+	cfg := rawFilebeatConfig{AutoIDMode: filestream.DefaultAutoIDMode}
+	if err := fb.rawConfig.Unpack(&cfg); err != nil {
+		return fmt.Errorf("failed to read filebeat.inputs: %w", err)
+	}
+
+	if err := validateFilestreamInputs(cfg, logp.NewLogger("filestream_validation")); err != nil {
+		logp.L().Errorf("%s", err)
+		if verr, ok := err.(*filestream.ValidationError); ok {
+			os.Exit(verr.ExitCode())
+		}
+		os.Exit(1)
+	}
+
+	return nil
+}
+
+// Stop is part of the beat.Beater interface.
+func (fb *Filebeat) Stop() {}
+
+// filestreamInputConfigs returns the configs in inputs whose `type` is
+// `filestream`, preserving their original position.
+func filestreamInputConfigs(inputs []*conf.C) []*conf.C {
+	var filestreamCfgs []*conf.C
+	for _, raw := range inputs {
+		var probe struct {
+			Type string `config:"type"`
+		}
+		if err := raw.Unpack(&probe); err != nil || probe.Type != "filestream" {
+			continue
+		}
+		filestreamCfgs = append(filestreamCfgs, raw)
+	}
+	return filestreamCfgs
+}
+
+// validateFilestreamInputs runs filestream.Validate over every filestream
+// input in cfg, writing the diagnostics file and returning the resulting
+// *filestream.ValidationError when an input is misconfigured, a plain error
+// when an input config cannot be read at all, or nil if there is nothing to
+// validate or every input is well-formed.
+func validateFilestreamInputs(cfg rawFilebeatConfig, logger *logp.Logger) error {
+	filestreamCfgs := filestreamInputConfigs(cfg.Inputs)
+	if len(filestreamCfgs) == 0 {
+		return nil
+	}
+
+	dataPath := paths.Resolve(paths.Data, "")
+	return filestream.Validate(filestreamCfgs, cfg.AutoIDMode, dataPath, logger)
+}
@@ -0,0 +1,88 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package beater
+
+import (
+	"testing"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/elastic/beats/v7/filebeat/input/filestream"
+)
+
+func mustNewConfigFrom(t *testing.T, v interface{}) *conf.C {
+	t.Helper()
+	c, err := conf.NewConfigFrom(v)
+	require.NoError(t, err)
+	return c
+}
+
+func TestFilestreamInputConfigsFiltersByType(t *testing.T) {
+	inputs := []*conf.C{
+		mustNewConfigFrom(t, map[string]interface{}{"type": "filestream", "paths": []string{"/var/log/a.log"}}),
+		mustNewConfigFrom(t, map[string]interface{}{"type": "tcp"}),
+		mustNewConfigFrom(t, map[string]interface{}{"type": "filestream", "paths": []string{"/var/log/b.log"}}),
+	}
+
+	got := filestreamInputConfigs(inputs)
+	require.Len(t, got, 2)
+
+	var first, second struct {
+		Paths []string `config:"paths"`
+	}
+	require.NoError(t, got[0].Unpack(&first))
+	require.NoError(t, got[1].Unpack(&second))
+	assert.Equal(t, []string{"/var/log/a.log"}, first.Paths)
+	assert.Equal(t, []string{"/var/log/b.log"}, second.Paths)
+}
+
+// TestValidateFilestreamInputsGeneratesIDsBeforeInputsExist exercises the same
+// path Run takes: an id-less filestream input, with auto id generation on,
+// must come out of validateFilestreamInputs with a stable generated id
+// already set on its config, since that's the only chance to set it before
+// the input manager turns cfg into a running input.
+func TestValidateFilestreamInputsGeneratesIDsBeforeInputsExist(t *testing.T) {
+	one := mustNewConfigFrom(t, map[string]interface{}{"type": "filestream", "paths": []string{"/var/log/a.log"}})
+
+	cfg := rawFilebeatConfig{
+		Inputs:     []*conf.C{one},
+		AutoIDMode: filestream.AutoIDGenerate,
+	}
+
+	err := validateFilestreamInputs(cfg, logp.NewLogger("filestream_validation_test"))
+	require.NoError(t, err)
+
+	var parsed struct {
+		ID string `config:"id"`
+	}
+	require.NoError(t, one.Unpack(&parsed))
+	assert.NotEmpty(t, parsed.ID)
+}
+
+func TestValidateFilestreamInputsSkippedWhenNoFilestreamInputs(t *testing.T) {
+	cfg := rawFilebeatConfig{
+		Inputs:     []*conf.C{mustNewConfigFrom(t, map[string]interface{}{"type": "tcp"})},
+		AutoIDMode: filestream.DefaultAutoIDMode,
+	}
+
+	err := validateFilestreamInputs(cfg, logp.NewLogger("filestream_validation_test"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,150 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/beats/v7/filebeat/registry"
+	"github.com/elastic/elastic-agent-libs/logp"
+	"github.com/elastic/elastic-agent-libs/paths"
+)
+
+// genRegistryCmd builds the `filebeat registry` command tree. It is added
+// to the root command in root.go, alongside the other maintenance commands
+// every beat gets (export, keystore, ...).
+func genRegistryCmd() *cobra.Command {
+	registryCmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Registry maintenance utilities",
+	}
+	registryCmd.AddCommand(genRegistryFilestreamCmd())
+	return registryCmd
+}
+
+func genRegistryFilestreamCmd() *cobra.Command {
+	filestreamCmd := &cobra.Command{
+		Use:   "filestream",
+		Short: "filestream registry maintenance utilities",
+	}
+	filestreamCmd.AddCommand(genRegistryFilestreamMigrateCmd())
+	return filestreamCmd
+}
+
+func genRegistryFilestreamMigrateCmd() *cobra.Command {
+	var (
+		mappingFlags []string
+		dryRun       bool
+		registryPath string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Rekey filestream registry entries without losing their read state",
+		Long: `migrate rewrites the keys of existing filestream registry entries, so that
+inputs whose id changed (including inputs that gained an id for the first
+time) keep resuming from their recorded offset instead of being re-ingested
+from scratch. Each --mapping is "old-id-or-empty=new-id", optionally followed
+by ":glob1,glob2" to only rekey entries whose recorded path matches one of
+those globs -- this is what lets a single old, empty id be split across
+several new ids in one run, by passing one --mapping per destination id with
+its own globs.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mappings, err := parseMappings(mappingFlags)
+			if err != nil {
+				return err
+			}
+
+			if registryPath == "" {
+				registryPath = paths.Resolve(paths.Data, "registry/filebeat")
+			}
+
+			logger := logp.NewLogger("registry_migrate")
+
+			if dryRun {
+				plans, err := registry.BuildPlan(registryPath, mappings)
+				if err != nil {
+					return err
+				}
+				if len(plans) == 0 {
+					fmt.Println("no matching registry entries found, nothing to do")
+					return nil
+				}
+				for _, p := range plans {
+					fmt.Printf("%s -> %s (path=%s)\n", p.OldKey, p.NewKey, p.Path)
+				}
+				return nil
+			}
+
+			plans, err := registry.Migrate(registryPath, mappings, logger)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("migrated %d registry entries\n", len(plans))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&mappingFlags, "mapping", nil,
+		`rekey mapping "old-id-or-empty=new-id[:glob1,glob2]", may be repeated`)
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the planned changes without modifying the registry")
+	cmd.Flags().StringVar(&registryPath, "registry-path", "", "path to the registry directory (defaults to path.data/registry/filebeat)")
+
+	return cmd
+}
+
+// parseMappings parses the repeated --mapping flags into registry.Mapping
+// values. Each flag is "old-id-or-empty=new-id", optionally followed by
+// ":glob1,glob2" to scope that one mapping to matching paths.
+func parseMappings(mappingFlags []string) ([]registry.Mapping, error) {
+	if len(mappingFlags) == 0 {
+		return nil, fmt.Errorf("at least one --mapping is required")
+	}
+
+	mappings := make([]registry.Mapping, 0, len(mappingFlags))
+	for _, raw := range mappingFlags {
+		idPart, newID, ok := strings.Cut(raw, "=")
+		if !ok {
+			return nil, fmt.Errorf(`invalid --mapping %q, expected format "old-id-or-empty=new-id[:glob1,glob2]"`, raw)
+		}
+
+		var globs []string
+		if destID, globList, hasGlobs := strings.Cut(newID, ":"); hasGlobs {
+			newID = destID
+			globs = strings.Split(globList, ",")
+		}
+
+		// Validated after stripping the optional ":glob1,glob2" suffix, so
+		// a typo like "old-id=:/tmp/foo.log" (missing destination id) is
+		// rejected instead of silently rekeying entries back to an empty
+		// id -- the exact problem this command exists to fix.
+		if newID == "" {
+			return nil, fmt.Errorf(`invalid --mapping %q, destination id must not be empty`, raw)
+		}
+
+		mappings = append(mappings, registry.Mapping{
+			OldID:     idPart,
+			NewID:     newID,
+			PathGlobs: globs,
+		})
+	}
+	return mappings, nil
+}
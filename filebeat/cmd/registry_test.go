@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import "testing"
+
+func TestParseMappingsSplitsGlobsFromDestinationID(t *testing.T) {
+	mappings, err := parseMappings([]string{"old-id=new-id:/tmp/a.log,/tmp/b.log"})
+	if err != nil {
+		t.Fatalf("parseMappings returned an error: %s", err)
+	}
+	if len(mappings) != 1 {
+		t.Fatalf("expected a single mapping, got %d", len(mappings))
+	}
+	m := mappings[0]
+	if m.OldID != "old-id" || m.NewID != "new-id" {
+		t.Fatalf("unexpected mapping %+v", m)
+	}
+	if len(m.PathGlobs) != 2 || m.PathGlobs[0] != "/tmp/a.log" || m.PathGlobs[1] != "/tmp/b.log" {
+		t.Fatalf("unexpected path globs %+v", m.PathGlobs)
+	}
+}
+
+func TestParseMappingsRejectsEmptyDestinationIDAfterGlobSuffix(t *testing.T) {
+	_, err := parseMappings([]string{"old-id=:/tmp/a.log"})
+	if err == nil {
+		t.Fatal("expected an error for a mapping with an empty destination id, got none")
+	}
+}
+
+func TestParseMappingsRejectsEmptyDestinationID(t *testing.T) {
+	_, err := parseMappings([]string{"old-id="})
+	if err == nil {
+		t.Fatal("expected an error for a mapping with an empty destination id, got none")
+	}
+}
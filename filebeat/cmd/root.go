@@ -0,0 +1,45 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package cmd assembles the filebeat binary's cobra command tree.
+package cmd
+
+import (
+	"github.com/elastic/beats/v7/filebeat/beater"
+	"github.com/elastic/beats/v7/libbeat/beat"
+	libbeatcmd "github.com/elastic/beats/v7/libbeat/cmd"
+	"github.com/elastic/beats/v7/libbeat/cmd/instance"
+)
+
+// Name of this beat.
+const Name = "filebeat"
+
+// RootCmd is the filebeat binary's entry point, run from main().
+var RootCmd *libbeatcmd.BeatsRootCmd
+
+func init() {
+	RootCmd = Filebeat(beater.New, instance.Settings{Name: Name})
+}
+
+// Filebeat assembles the root command for the given beat.Creator and
+// settings, adding filebeat-specific subcommands on top of the ones every
+// beat gets (run, export, keystore, ...).
+func Filebeat(beatCreator beat.Creator, settings instance.Settings) *libbeatcmd.BeatsRootCmd {
+	rootCmd := libbeatcmd.GenRootCmdWithSettings(beatCreator, settings)
+	rootCmd.AddCommand(genRegistryCmd())
+	return rootCmd
+}
@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filestream
+
+import "fmt"
+
+// AutoIDMode controls what happens when a filestream input is configured
+// without an explicit `id`.
+type AutoIDMode string
+
+const (
+	// AutoIDOff preserves the historical behaviour: an input without an
+	// `id` fails validation and prevents Filebeat from starting.
+	AutoIDOff AutoIDMode = "off"
+	// AutoIDWarn generates a deterministic id for the input, but logs a
+	// warning so operators notice and can pin an explicit id later.
+	AutoIDWarn AutoIDMode = "warn"
+	// AutoIDGenerate generates a deterministic id for the input silently.
+	AutoIDGenerate AutoIDMode = "generate"
+)
+
+// DefaultAutoIDMode is used when `filebeat.input_auto_id` is not set,
+// matching the behaviour Filebeat had before this setting was introduced.
+const DefaultAutoIDMode = AutoIDOff
+
+// Unpack implements go-ucfg's Unpacker interface so AutoIDMode can be used
+// directly as a config field and rejects unknown values at load time.
+func (m *AutoIDMode) Unpack(value string) error {
+	switch AutoIDMode(value) {
+	case AutoIDOff, AutoIDWarn, AutoIDGenerate:
+		*m = AutoIDMode(value)
+		return nil
+	default:
+		return fmt.Errorf("invalid value %q for filebeat.input_auto_id, must be one of 'off', 'warn', 'generate'", value)
+	}
+}
@@ -0,0 +1,57 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filestream
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// diagnosticsFileName is the file Validate writes under path.data when it
+// finds one or more misconfigured inputs, so that orchestrators can inspect
+// the failure without scraping the log.
+const diagnosticsFileName = "filestream-validation.json"
+
+// diagnosticsDoc is the schema written to
+// path.data/diagnostics/filestream-validation.json.
+type diagnosticsDoc struct {
+	Problems []Problem `json:"problems"`
+}
+
+// writeDiagnostics writes problems as diagnosticsDoc to
+// <dataPath>/diagnostics/filestream-validation.json, creating the
+// diagnostics directory if needed.
+func writeDiagnostics(dataPath string, problems []Problem) error {
+	dir := filepath.Join(dataPath, "diagnostics")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create diagnostics dir %q: %w", dir, err)
+	}
+
+	data, err := json.MarshalIndent(diagnosticsDoc{Problems: problems}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal filestream validation diagnostics: %w", err)
+	}
+
+	file := filepath.Join(dir, diagnosticsFileName)
+	if err := os.WriteFile(file, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write filestream validation diagnostics to %q: %w", file, err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filestream
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// hostSalt is mixed into every generated id so that the same configuration
+// deployed to two different hosts does not produce the same registry key.
+// It is resolved once per process and cached, since it never changes for
+// the lifetime of a running Filebeat. hostSaltOnce guards that resolution
+// so concurrent reloads calling hostIDSalt() can't race on it.
+var (
+	hostSaltOnce sync.Once
+	hostSalt     string
+)
+
+// generateID derives a stable input id from the input type and the set of
+// configured paths. The paths are sorted and cleaned first so that
+// reordering them in the config, or the OS reporting them in a different
+// order, does not change the result. salt is normally the value returned by
+// hostIDSalt(), but is passed explicitly so tests can pin it.
+func generateID(inputType string, paths []string, salt string) string {
+	normalized := make([]string, len(paths))
+	for i, p := range paths {
+		normalized[i] = filepath.Clean(p)
+	}
+	sort.Strings(normalized)
+
+	h := sha256.New()
+	h.Write([]byte(inputType))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(normalized, "\x00")))
+	h.Write([]byte{0})
+	h.Write([]byte(salt))
+
+	return "auto_" + hex.EncodeToString(h.Sum(nil))[:24]
+}
+
+// hostIDSalt returns a per-host value used to salt generated ids, so that
+// identical configurations on different hosts never collide in a shared
+// output. It combines the hostname with the machine id when available,
+// falling back to the hostname alone.
+func hostIDSalt() string {
+	hostSaltOnce.Do(func() {
+		hostname, _ := os.Hostname()
+		hostSalt = hostname + "/" + readMachineID()
+	})
+	return hostSalt
+}
+
+// readMachineID best-effort reads the Linux machine-id. On platforms or
+// systems where it is not available, it returns an empty string and callers
+// fall back to the hostname alone.
+func readMachineID() string {
+	for _, p := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+		if data, err := os.ReadFile(p); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+	}
+	return ""
+}
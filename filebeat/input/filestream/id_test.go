@@ -0,0 +1,47 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filestream
+
+import "testing"
+
+func TestGenerateIDIsStable(t *testing.T) {
+	first := generateID("filestream", []string{"/var/log/a.log", "/var/log/b.log"}, "host-salt")
+	second := generateID("filestream", []string{"/var/log/b.log", "/var/log/a.log"}, "host-salt")
+
+	if first != second {
+		t.Fatalf("expected id to be stable regardless of path order, got %q and %q", first, second)
+	}
+}
+
+func TestGenerateIDDiffersByPaths(t *testing.T) {
+	a := generateID("filestream", []string{"/var/log/a.log"}, "host-salt")
+	b := generateID("filestream", []string{"/var/log/b.log"}, "host-salt")
+
+	if a == b {
+		t.Fatalf("expected different paths to generate different ids, both were %q", a)
+	}
+}
+
+func TestGenerateIDDiffersBySalt(t *testing.T) {
+	a := generateID("filestream", []string{"/var/log/a.log"}, "host-one")
+	b := generateID("filestream", []string{"/var/log/a.log"}, "host-two")
+
+	if a == b {
+		t.Fatalf("expected different host salts to generate different ids, both were %q", a)
+	}
+}
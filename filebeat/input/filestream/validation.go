@@ -0,0 +1,205 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filestream
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	conf "github.com/elastic/elastic-agent-libs/config"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// rawInputConfig is the subset of a filestream input's configuration that
+// validation and id generation need.
+type rawInputConfig struct {
+	ID    string   `config:"id"`
+	Paths []string `config:"paths"`
+}
+
+// Validate is called by the beater, once at startup, with every raw
+// `filestream` input config, the configured `filebeat.input_auto_id`
+// mode, and path.data. It fills in a deterministic id for any input
+// missing one according to mode (mutating cfgs in place), then checks
+// every input for duplicate ids, empty ids, invalid glob patterns and
+// unreadable paths.
+//
+// If any problem is found, Validate writes a diagnostics document to
+// <dataPath>/diagnostics/filestream-validation.json and returns a
+// *ValidationError describing every problem, with an ExitCode the caller
+// should pass to os.Exit so orchestrators can distinguish a bad config
+// from a transient failure.
+func Validate(cfgs []*conf.C, mode AutoIDMode, dataPath string, logger *logp.Logger) error {
+	salt := hostIDSalt()
+
+	parsed := make([]rawInputConfig, len(cfgs))
+	for i, c := range cfgs {
+		if err := c.Unpack(&parsed[i]); err != nil {
+			return fmt.Errorf("filestream inputs validation error: failed to read input %d: %w", i, err)
+		}
+
+		if parsed[i].ID != "" || mode == AutoIDOff {
+			continue
+		}
+
+		id := generateID("filestream", parsed[i].Paths, salt)
+		if mode == AutoIDWarn {
+			logger.Warnf("filestream input %d has no id, generated id %q from its paths; set an explicit id to silence this warning", i, id)
+		}
+
+		if err := cfgs[i].SetString("id", -1, id); err != nil {
+			return fmt.Errorf("filestream inputs validation error: failed to set generated id on input %d: %w", i, err)
+		}
+		parsed[i].ID = id
+	}
+
+	var problems []Problem
+	problems = append(problems, findIDProblems(parsed)...)
+	problems = append(problems, findPathProblems(parsed)...)
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if err := writeDiagnostics(dataPath, problems); err != nil {
+		logger.Errorf("%s", err)
+	}
+
+	return &ValidationError{Problems: problems}
+}
+
+// findIDProblems flags inputs with an empty id, and inputs that share an id
+// with another input -- whether that id was set explicitly or generated.
+func findIDProblems(inputs []rawInputConfig) []Problem {
+	byID := make(map[string][]int, len(inputs))
+	for i, in := range inputs {
+		byID[in.ID] = append(byID[in.ID], i)
+	}
+
+	var problems []Problem
+	for id, indexes := range byID {
+		switch {
+		case id == "":
+			for _, i := range indexes {
+				problems = append(problems, Problem{
+					Index:  i,
+					ID:     "",
+					Paths:  inputs[i].Paths,
+					Class:  ProblemEmptyID,
+					Reason: "input has no id and filebeat.input_auto_id is off",
+				})
+			}
+		case len(indexes) > 1:
+			for _, i := range indexes {
+				problems = append(problems, Problem{
+					Index:  i,
+					ID:     id,
+					Paths:  inputs[i].Paths,
+					Class:  ProblemDuplicateID,
+					Reason: fmt.Sprintf("id %q is also used by input(s) %v", id, without(indexes, i)),
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// findPathProblems flags inputs whose paths contain an invalid glob pattern
+// or whose non-glob parent directory exists but cannot be read.
+func findPathProblems(inputs []rawInputConfig) []Problem {
+	var problems []Problem
+	for i, in := range inputs {
+		for _, p := range in.Paths {
+			if _, err := filepath.Match(p, ""); err != nil {
+				problems = append(problems, Problem{
+					Index:  i,
+					ID:     in.ID,
+					Paths:  in.Paths,
+					Class:  ProblemInvalidGlob,
+					Reason: fmt.Sprintf("path %q is not a valid glob pattern: %s", p, err),
+				})
+				continue
+			}
+
+			dir := globParentDir(p)
+			f, err := os.Open(dir)
+			if err != nil {
+				if os.IsPermission(err) {
+					problems = append(problems, Problem{
+						Index:  i,
+						ID:     in.ID,
+						Paths:  in.Paths,
+						Class:  ProblemUnreadablePath,
+						Reason: fmt.Sprintf("path %q is not readable: %s", dir, err),
+					})
+				}
+				continue
+			}
+			f.Close()
+		}
+	}
+	return problems
+}
+
+// globParentDir returns the deepest directory in pattern that contains no
+// glob metacharacters in it or in any of its parents, i.e. the directory
+// filepath.Glob would start scanning from. A wildcard in a segment that
+// isn't the last one (e.g. "/var/log/*/app/*.log") still stops the walk at
+// its parent, rather than being skipped over.
+func globParentDir(pattern string) string {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+
+	safe := segments[:0:0]
+	for _, seg := range segments {
+		if strings.ContainsAny(seg, "*?[") {
+			break
+		}
+		safe = append(safe, seg)
+	}
+
+	if len(safe) == len(segments) {
+		// No wildcard anywhere in pattern; use its literal parent directory.
+		return filepath.Dir(pattern)
+	}
+
+	dir := strings.Join(safe, "/")
+	if dir == "" {
+		// No literal leading segment survived, e.g. "*.log" or "*/app.log".
+		// An absolute pattern ("/*.log") still resolves from root; a
+		// relative one resolves from the working directory, matching what
+		// filepath.Dir would return for the equivalent literal path.
+		if strings.HasPrefix(pattern, "/") {
+			dir = "/"
+		} else {
+			dir = "."
+		}
+	}
+	return filepath.FromSlash(dir)
+}
+
+func without(indexes []int, exclude int) []int {
+	out := make([]int, 0, len(indexes)-1)
+	for _, i := range indexes {
+		if i != exclude {
+			out = append(out, i)
+		}
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filestream
+
+import "fmt"
+
+// ProblemClass identifies why a filestream input failed validation. Each
+// class maps to its own process exit code (see ExitCode) so that
+// orchestrators such as systemd, Kubernetes or Ansible can tell "this
+// config is broken, do not restart" apart from a transient failure, and
+// tell the different kinds of broken config apart from each other.
+type ProblemClass string
+
+const (
+	ProblemDuplicateID    ProblemClass = "duplicate_id"
+	ProblemEmptyID        ProblemClass = "empty_id"
+	ProblemInvalidGlob    ProblemClass = "invalid_glob"
+	ProblemUnreadablePath ProblemClass = "unreadable_path"
+)
+
+// Exit codes for filestream input validation failures. They start at 78
+// (EX_CONFIG in BSD sysexits.h) and count up, one per ProblemClass, so a
+// config problem never aliases the generic exit code Filebeat already uses
+// for unrelated startup failures.
+const (
+	ExitCodeDuplicateID    = 78
+	ExitCodeEmptyID        = 79
+	ExitCodeInvalidGlob    = 80
+	ExitCodeUnreadablePath = 81
+)
+
+var exitCodes = map[ProblemClass]int{
+	ProblemDuplicateID:    ExitCodeDuplicateID,
+	ProblemEmptyID:        ExitCodeEmptyID,
+	ProblemInvalidGlob:    ExitCodeInvalidGlob,
+	ProblemUnreadablePath: ExitCodeUnreadablePath,
+}
+
+// Problem describes a single offending input found during validation.
+type Problem struct {
+	Index  int          `json:"index"`
+	ID     string       `json:"id"`
+	Paths  []string     `json:"paths"`
+	Class  ProblemClass `json:"reason_class"`
+	Reason string       `json:"reason"`
+}
+
+// ValidationError is returned by Validate when one or more filestream
+// inputs are misconfigured. It carries every Problem found so the caller
+// can both log a human-readable summary and write the machine-readable
+// diagnostics file.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("filestream inputs validation error: %d problem(s) found, see %s for details",
+		len(e.Problems), diagnosticsFileName)
+}
+
+// ExitCode returns the process exit code Filebeat should use for this
+// error. When problems of several classes are found in the same run, the
+// lowest exit code (i.e. the first class checked) wins, so the exit code
+// is always deterministic for a given config.
+func (e *ValidationError) ExitCode() int {
+	code := 0
+	for _, p := range e.Problems {
+		c := exitCodes[p.Class]
+		if code == 0 || c < code {
+			code = c
+		}
+	}
+	return code
+}
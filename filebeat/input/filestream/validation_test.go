@@ -0,0 +1,105 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package filestream
+
+import "testing"
+
+func TestFindIDProblemsDetectsDuplicates(t *testing.T) {
+	inputs := []rawInputConfig{
+		{ID: "dup", Paths: []string{"/tmp/a.log"}},
+		{ID: "dup", Paths: []string{"/tmp/b.log"}},
+		{ID: "unique", Paths: []string{"/tmp/c.log"}},
+	}
+
+	problems := findIDProblems(inputs)
+	if len(problems) != 2 {
+		t.Fatalf("expected 2 problems for the duplicated id, got %d: %+v", len(problems), problems)
+	}
+	for _, p := range problems {
+		if p.Class != ProblemDuplicateID {
+			t.Fatalf("expected ProblemDuplicateID, got %v", p.Class)
+		}
+	}
+}
+
+func TestFindIDProblemsDetectsEmptyID(t *testing.T) {
+	inputs := []rawInputConfig{
+		{ID: "", Paths: []string{"/tmp/a.log"}},
+	}
+
+	problems := findIDProblems(inputs)
+	if len(problems) != 1 || problems[0].Class != ProblemEmptyID {
+		t.Fatalf("expected a single ProblemEmptyID, got %+v", problems)
+	}
+}
+
+func TestFindPathProblemsDetectsInvalidGlob(t *testing.T) {
+	inputs := []rawInputConfig{
+		{ID: "bad-glob", Paths: []string{"/tmp/[unterminated"}},
+	}
+
+	problems := findPathProblems(inputs)
+	if len(problems) != 1 || problems[0].Class != ProblemInvalidGlob {
+		t.Fatalf("expected a single ProblemInvalidGlob, got %+v", problems)
+	}
+}
+
+func TestGlobParentDirStripsWildcardSegments(t *testing.T) {
+	got := globParentDir("/var/log/app/*.log")
+	want := "/var/log/app"
+	if got != want {
+		t.Fatalf("globParentDir() = %q, want %q", got, want)
+	}
+}
+
+func TestGlobParentDirStopsAtFirstWildcardSegment(t *testing.T) {
+	got := globParentDir("/var/log/*/app/*.log")
+	want := "/var/log"
+	if got != want {
+		t.Fatalf("globParentDir() = %q, want %q", got, want)
+	}
+}
+
+func TestGlobParentDirOfRelativePatternIsWorkingDirectory(t *testing.T) {
+	for _, pattern := range []string{"*.log", "*/app.log"} {
+		got := globParentDir(pattern)
+		want := "."
+		if got != want {
+			t.Fatalf("globParentDir(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestGlobParentDirOfAbsoluteWildcardFirstSegmentIsRoot(t *testing.T) {
+	got := globParentDir("/*.log")
+	want := "/"
+	if got != want {
+		t.Fatalf("globParentDir() = %q, want %q", got, want)
+	}
+}
+
+func TestValidationErrorExitCodePicksLowest(t *testing.T) {
+	err := &ValidationError{Problems: []Problem{
+		{Class: ProblemUnreadablePath},
+		{Class: ProblemDuplicateID},
+	}}
+
+	if got := err.ExitCode(); got != ExitCodeDuplicateID {
+		t.Fatalf("ExitCode() = %d, want %d", got, ExitCodeDuplicateID)
+	}
+}
@@ -0,0 +1,244 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package registry contains offline maintenance tools for Filebeat's
+// on-disk registry, the statestore-backed file that tracks per-input,
+// per-file read state under data/registry/filebeat.
+package registry
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/elastic/beats/v7/libbeat/statestore"
+	"github.com/elastic/beats/v7/libbeat/statestore/backend/memlog"
+	"github.com/elastic/elastic-agent-libs/logp"
+)
+
+// keySeparator mirrors the separator filestream uses to join an input's id
+// with the per-file identifier when building a registry key, e.g.
+// "filestream::my-id::native::1234-5678". Entries recorded before an input
+// had an id look the same but with the id segment left empty:
+// "filestream::::native::1234-5678".
+const keySeparator = "::"
+
+// Mapping describes one rekey operation: every registry entry belonging to
+// OldID (empty string matches entries recorded with no id) is rewritten to
+// belong to NewID instead. PathGlobs, when non-empty, additionally
+// restricts the rewrite to entries whose recorded source path matches at
+// least one of the globs, so a single old, empty id can be split across
+// several new ids -- pass one Mapping per destination id, each with its own
+// PathGlobs, rather than sharing one glob list across mappings.
+type Mapping struct {
+	OldID     string
+	NewID     string
+	PathGlobs []string
+}
+
+// Plan is one planned rekey: the exact registry key being replaced, its
+// replacement, and the source path it was matched on, for --dry-run output
+// and logging.
+type Plan struct {
+	OldKey string
+	NewKey string
+	Path   string
+}
+
+// entry is the subset of a filestream registry value that migration needs
+// to read in order to match it against a Mapping's path globs.
+type entry struct {
+	Source string `json:"source"`
+}
+
+// BuildPlan opens the registry at registryPath read-only and computes the
+// set of rekey operations implied by mappings, without modifying anything.
+// It is used both to implement --dry-run and as the first step of Migrate.
+func BuildPlan(registryPath string, mappings []Mapping) ([]Plan, error) {
+	store, closeStore, err := openStore(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	defer closeStore()
+
+	return planFromStore(store, mappings)
+}
+
+// planFromStore scans an already-open store and computes the rekey
+// operations implied by mappings. It is shared by BuildPlan, which opens
+// its own short-lived store handle, and Migrate, which reuses the handle
+// it holds for the writes that follow -- opening the registry twice would
+// make the second open contend for memlog's exclusive file lock against
+// the first, still-open handle.
+func planFromStore(store *statestore.Store, mappings []Mapping) ([]Plan, error) {
+	var plans []Plan
+	err := store.Each(func(key string, dec statestore.ValueDecoder) (bool, error) {
+		id, ok := idFromKey(key)
+		if !ok {
+			return true, nil
+		}
+		candidates := mappingsForOldID(mappings, id)
+		if len(candidates) == 0 {
+			return true, nil
+		}
+
+		var e entry
+		if err := dec.Decode(&e); err != nil {
+			return true, fmt.Errorf("failed to decode registry entry %q: %w", key, err)
+		}
+
+		m := selectMapping(candidates, e.Source)
+		if m == nil {
+			return true, nil
+		}
+
+		plans = append(plans, Plan{
+			OldKey: key,
+			NewKey: rekey(key, m.NewID),
+			Path:   e.Source,
+		})
+		return true, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan registry: %w", err)
+	}
+
+	sort.Slice(plans, func(i, j int) bool { return plans[i].OldKey < plans[j].OldKey })
+	return plans, nil
+}
+
+// Migrate applies the rekey operations computed by planFromStore: for every
+// matching entry it writes the value under its new key, then removes the
+// old key. The statestore has no multi-key atomic write, so these are two
+// independent calls rather than one transaction -- a crash between them
+// leaves the entry present under both the old and the new key, never
+// missing under both. That duplicate is harmless: the input config that
+// produced OldKey is gone, so nothing reads it again, and re-running
+// Migrate is safe, since a missing OldKey with its NewKey already in place
+// is treated as "already migrated" and skipped rather than an error.
+func Migrate(registryPath string, mappings []Mapping, logger *logp.Logger) ([]Plan, error) {
+	store, closeStore, err := openStore(registryPath)
+	if err != nil {
+		return nil, err
+	}
+	defer closeStore()
+
+	plans, err := planFromStore(store, mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range plans {
+		var raw map[string]interface{}
+		if err := store.Get(p.OldKey, &raw); err != nil {
+			if store.Has(p.NewKey) {
+				logger.Infof("entry %q was already migrated to %q by a previous run, skipping", p.OldKey, p.NewKey)
+				continue
+			}
+			return nil, fmt.Errorf("failed to read entry %q before migrating it: %w", p.OldKey, err)
+		}
+
+		if err := store.Set(p.NewKey, raw); err != nil {
+			return nil, fmt.Errorf("failed to write migrated entry %q: %w", p.NewKey, err)
+		}
+		if err := store.Remove(p.OldKey); err != nil {
+			return nil, fmt.Errorf("failed to remove migrated entry %q: %w", p.OldKey, err)
+		}
+		logger.Infof("migrated registry entry %q to %q", p.OldKey, p.NewKey)
+	}
+
+	return plans, nil
+}
+
+func openStore(registryPath string) (*statestore.Store, func(), error) {
+	backend, err := memlog.New(logp.NewLogger("registry_migrate"), memlog.Settings{
+		Root:     registryPath,
+		FileMode: 0600,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open registry at %q: %w", registryPath, err)
+	}
+
+	store, err := statestore.NewRegistrarStore(backend)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open registry store at %q: %w", registryPath, err)
+	}
+
+	return store, func() { store.Close() }, nil
+}
+
+// mappingsForOldID returns every mapping whose OldID matches id, in the
+// order they were given, since several mappings (each with its own
+// PathGlobs) may share the same old, usually empty, id.
+func mappingsForOldID(mappings []Mapping, id string) []Mapping {
+	var out []Mapping
+	for _, m := range mappings {
+		if m.OldID == id {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// selectMapping picks which of the candidate mappings (all sharing the same
+// OldID) applies to source. A mapping with PathGlobs only applies when
+// source matches one of them; a mapping with no PathGlobs matches anything
+// and is used as the fallback when no glob-restricted candidate matches.
+func selectMapping(candidates []Mapping, source string) *Mapping {
+	var fallback *Mapping
+	for i := range candidates {
+		c := &candidates[i]
+		if len(c.PathGlobs) == 0 {
+			if fallback == nil {
+				fallback = c
+			}
+			continue
+		}
+		if matchesAnyGlob(source, c.PathGlobs) {
+			return c
+		}
+	}
+	return fallback
+}
+
+// idFromKey extracts the input id segment from a "filestream::<id>::..."
+// registry key.
+func idFromKey(key string) (string, bool) {
+	parts := strings.SplitN(key, keySeparator, 3)
+	if len(parts) < 3 || parts[0] != "filestream" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// rekey replaces the id segment of a "filestream::<id>::..." key with
+// newID, leaving the per-file identifier suffix untouched.
+func rekey(key, newID string) string {
+	parts := strings.SplitN(key, keySeparator, 3)
+	parts[1] = newID
+	return strings.Join(parts, keySeparator)
+}
+
+func matchesAnyGlob(path string, globs []string) bool {
+	for _, g := range globs {
+		if ok, err := filepath.Match(g, path); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
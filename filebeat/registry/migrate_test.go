@@ -0,0 +1,52 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package registry
+
+import "testing"
+
+func TestSelectMappingUsesMatchingGlobOverFallback(t *testing.T) {
+	candidates := []Mapping{
+		{OldID: "", NewID: "first-log", PathGlobs: []string{"/tmp/first.log"}},
+		{OldID: "", NewID: "second-log", PathGlobs: []string{"/tmp/second.log"}},
+	}
+
+	m := selectMapping(candidates, "/tmp/second.log")
+	if m == nil || m.NewID != "second-log" {
+		t.Fatalf("expected source to be routed to second-log, got %+v", m)
+	}
+}
+
+func TestSelectMappingFallsBackToGlobless(t *testing.T) {
+	candidates := []Mapping{
+		{OldID: "", NewID: "first-log", PathGlobs: []string{"/tmp/first.log"}},
+		{OldID: "", NewID: "default-log"},
+	}
+
+	m := selectMapping(candidates, "/tmp/unrelated.log")
+	if m == nil || m.NewID != "default-log" {
+		t.Fatalf("expected unmatched source to fall back to the globless mapping, got %+v", m)
+	}
+}
+
+func TestRekeyPreservesSuffix(t *testing.T) {
+	got := rekey("filestream::::native::1-1", "first-log")
+	want := "filestream::first-log::native::1-1"
+	if got != want {
+		t.Fatalf("rekey() = %q, want %q", got, want)
+	}
+}
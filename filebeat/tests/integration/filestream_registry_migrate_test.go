@@ -0,0 +1,158 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastic/beats/v7/libbeat/tests/integration"
+)
+
+var filestreamMigratedCfg = `
+filebeat.inputs:
+  - type: filestream
+    id: first-log
+    enabled: true
+    paths:
+      - %s
+  - type: filestream
+    id: second-log
+    enabled: true
+    paths:
+      - %s
+
+path.home: %s
+
+output.file:
+  path: ${path.home}
+  filename: "output-file"
+
+logging:
+  level: debug
+  metrics:
+    enabled: false
+`
+
+func TestFilestreamRegistryMigrateDryRun(t *testing.T) {
+	filebeat := integration.NewBeat(
+		t,
+		"filebeat",
+		"../../filebeat.test",
+	)
+	tempDir := filebeat.TempDir()
+
+	firstLog := path.Join(tempDir, "first.log")
+	secondLog := path.Join(tempDir, "second.log")
+	integration.GenerateLogFile(t, firstLog, 5, false)
+	integration.GenerateLogFile(t, secondLog, 5, false)
+
+	registryDir := filepath.Join(tempDir, "data", "registry", "filebeat")
+	seedLegacyRegistry(t, registryDir, firstLog, secondLog)
+
+	filebeat.WriteConfigFile(fmt.Sprintf(filestreamMigratedCfg, firstLog, secondLog, tempDir))
+	filebeat.Start(
+		"registry", "filestream", "migrate",
+		"--registry-path", registryDir,
+		"--mapping", fmt.Sprintf("=first-log:%s", firstLog),
+		"--mapping", fmt.Sprintf("=second-log:%s", secondLog),
+		"--dry-run",
+	)
+
+	filebeat.WaitForLogs(
+		"filestream::::",
+		10*time.Second,
+		"migrate --dry-run did not print the planned rekey of the legacy entries")
+}
+
+func TestFilestreamRegistryMigrateThenRestartDoesNotReingest(t *testing.T) {
+	filebeat := integration.NewBeat(
+		t,
+		"filebeat",
+		"../../filebeat.test",
+	)
+	tempDir := filebeat.TempDir()
+
+	firstLog := path.Join(tempDir, "first.log")
+	secondLog := path.Join(tempDir, "second.log")
+	integration.GenerateLogFile(t, firstLog, 5, false)
+	integration.GenerateLogFile(t, secondLog, 5, false)
+
+	registryDir := filepath.Join(tempDir, "data", "registry", "filebeat")
+	seedLegacyRegistry(t, registryDir, firstLog, secondLog)
+
+	filebeat.Start(
+		"registry", "filestream", "migrate",
+		"--registry-path", registryDir,
+		"--mapping", fmt.Sprintf("=first-log:%s", firstLog),
+		"--mapping", fmt.Sprintf("=second-log:%s", secondLog),
+	)
+	proc, err := filebeat.Process.Wait()
+	if err != nil || !proc.Success() {
+		t.Fatalf("registry filestream migrate did not exit successfully: %v", err)
+	}
+
+	registryFile := filepath.Join(registryDir, "log.json")
+	filebeat.WaitFileContains(registryFile, `"op":"set"`, 10*time.Second)
+	filebeat.WaitFileContains(registryFile, "filestream::first-log::", time.Second)
+	filebeat.WaitFileContains(registryFile, "filestream::second-log::", time.Second)
+
+	// Restart with the now-explicit ids; since the migrated keys already
+	// record both files as fully read, neither should be ingested again.
+	filebeat.WriteConfigFile(fmt.Sprintf(filestreamMigratedCfg, firstLog, secondLog, tempDir))
+	filebeat.Start()
+
+	filebeat.WaitForLogs(
+		"Input 'filestream' starting",
+		10*time.Second,
+		"Filebeat did not start after the registry migration")
+	filebeat.WaitForLogs(
+		fmt.Sprintf("Resuming existing file: %s", firstLog),
+		10*time.Second,
+		"Filebeat did not resume the first file from the migrated registry state")
+	filebeat.WaitForLogs(
+		fmt.Sprintf("Resuming existing file: %s", secondLog),
+		10*time.Second,
+		"Filebeat did not resume the second file from the migrated registry state")
+}
+
+// seedLegacyRegistry writes a registry log.json as if two filestream inputs
+// without an id had already read firstPath and secondPath in full, the
+// starting point for the migrate command under test.
+func seedLegacyRegistry(t *testing.T, registryDir, firstPath, secondPath string) {
+	t.Helper()
+
+	if err := os.MkdirAll(registryDir, 0o755); err != nil {
+		t.Fatalf("failed to create registry dir: %s", err)
+	}
+
+	entries := fmt.Sprintf(
+		`{"op":"set","k":"filestream::::native::1-1","v":{"source":%q,"offset":1024,"ttl":-1}}
+{"op":"set","k":"filestream::::native::2-2","v":{"source":%q,"offset":2048,"ttl":-1}}
+`, firstPath, secondPath)
+
+	if err := os.WriteFile(filepath.Join(registryDir, "log.json"), []byte(entries), 0o644); err != nil {
+		t.Fatalf("failed to seed legacy registry: %s", err)
+	}
+}
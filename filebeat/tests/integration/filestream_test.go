@@ -20,7 +20,9 @@
 package integration
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"path"
 	"path/filepath"
 	"testing"
@@ -271,3 +273,325 @@ logging:
 		10*time.Second,
 		"Filebeat did log a validation error")
 }
+
+var filestreamInvalidGlobCfg = `
+filebeat.inputs:
+  - type: filestream
+    id: bad-glob
+    enabled: true
+    paths:
+      - "/tmp/[unterminated"
+
+output.discard.enabled: true
+logging:
+  level: debug
+  metrics:
+    enabled: false
+`
+
+var filestreamUnreadablePathCfg = `
+filebeat.inputs:
+  - type: filestream
+    id: unreadable-path
+    enabled: true
+    paths:
+      - %s
+
+output.discard.enabled: true
+logging:
+  level: debug
+  metrics:
+    enabled: false
+`
+
+var filestreamDuplicatedIDCfg = `
+filebeat.inputs:
+  - type: filestream
+    id: duplicated-id-1
+    enabled: true
+    paths:
+      - /tmp/*.log
+  - type: filestream
+    id: duplicated-id-1
+    enabled: true
+    paths:
+      - /var/log/*.log
+
+output.discard.enabled: true
+logging:
+  level: debug
+  metrics:
+    enabled: false
+`
+
+var filestreamEmptyIDCfg = `
+filebeat.inputs:
+  - type: filestream
+    enabled: true
+    paths:
+      - /tmp/*.log
+  - type: filestream
+    enabled: true
+    paths:
+      - /var/log/*.log
+
+output.discard.enabled: true
+logging:
+  level: debug
+  metrics:
+    enabled: false
+`
+
+// filestreamDiagnosticsDoc mirrors the schema written by filestream's
+// validation to <path.data>/diagnostics/filestream-validation.json.
+type filestreamDiagnosticsDoc struct {
+	Problems []struct {
+		Index  int      `json:"index"`
+		ID     string   `json:"id"`
+		Paths  []string `json:"paths"`
+		Class  string   `json:"reason_class"`
+		Reason string   `json:"reason"`
+	} `json:"problems"`
+}
+
+func TestFilestreamValidationExitCodesAndDiagnostics(t *testing.T) {
+	tcs := []struct {
+		name         string
+		wantExitCode int
+		wantClass    string
+		// buildCfg returns the config to write and, if non-empty, the path
+		// of an offending input so the test can assert the diagnostics
+		// entry names it.
+		buildCfg func(t *testing.T, tempDir string) (cfg, offendingPath string)
+	}{
+		{
+			name:         "duplicated id",
+			wantExitCode: 78,
+			wantClass:    "duplicate_id",
+			buildCfg: func(t *testing.T, tempDir string) (string, string) {
+				return filestreamDuplicatedIDCfg, ""
+			},
+		},
+		{
+			name:         "empty id",
+			wantExitCode: 79,
+			wantClass:    "empty_id",
+			buildCfg: func(t *testing.T, tempDir string) (string, string) {
+				return filestreamEmptyIDCfg, ""
+			},
+		},
+		{
+			name:         "invalid glob",
+			wantExitCode: 80,
+			wantClass:    "invalid_glob",
+			buildCfg: func(t *testing.T, tempDir string) (string, string) {
+				return filestreamInvalidGlobCfg, "/tmp/[unterminated"
+			},
+		},
+		{
+			name:         "unreadable path",
+			wantExitCode: 81,
+			wantClass:    "unreadable_path",
+			buildCfg: func(t *testing.T, tempDir string) (string, string) {
+				if os.Geteuid() == 0 {
+					t.Skip("directory permissions are not enforced for root, skipping")
+				}
+
+				unreadableDir := filepath.Join(tempDir, "unreadable")
+				require.NoError(t, os.Mkdir(unreadableDir, 0o755))
+				require.NoError(t, os.Chmod(unreadableDir, 0o000))
+				t.Cleanup(func() { os.Chmod(unreadableDir, 0o755) })
+
+				logPath := filepath.Join(unreadableDir, "*.log")
+				return fmt.Sprintf(filestreamUnreadablePathCfg, logPath), logPath
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			filebeat := integration.NewBeat(
+				t,
+				"filebeat",
+				"../../filebeat.test",
+			)
+			tempDir := filebeat.TempDir()
+
+			cfg, offendingPath := tc.buildCfg(t, tempDir)
+
+			filebeat.WriteConfigFile(fmt.Sprintf("path.home: %s\n%s", tempDir, cfg))
+			filebeat.Start()
+
+			filebeat.WaitForLogs(
+				"filestream inputs validation error",
+				10*time.Second,
+				"Filebeat did not log a filestream input validation error")
+
+			proc, err := filebeat.Process.Wait()
+			require.NoError(t, err, "filebeat process.Wait returned an error")
+			assert.False(t, proc.Success(), "filebeat should have failed to start")
+			assert.Equal(t, tc.wantExitCode, proc.ExitCode(), "unexpected exit code for %s", tc.name)
+
+			diagnosticsFile := filepath.Join(tempDir, "data", "diagnostics", "filestream-validation.json")
+			filebeat.WaitFileContains(diagnosticsFile, tc.wantClass, 10*time.Second)
+
+			raw, err := os.ReadFile(diagnosticsFile)
+			require.NoError(t, err, "failed to read diagnostics file")
+
+			var doc filestreamDiagnosticsDoc
+			require.NoError(t, json.Unmarshal(raw, &doc), "diagnostics file is not valid JSON matching the documented schema")
+			require.NotEmpty(t, doc.Problems, "diagnostics file lists no problems")
+
+			var found bool
+			for _, p := range doc.Problems {
+				if p.Class != tc.wantClass {
+					continue
+				}
+				found = true
+				assert.GreaterOrEqual(t, p.Index, 0, "problem index must be set")
+				assert.NotEmpty(t, p.Reason, "problem must carry a human-readable reason")
+				if offendingPath != "" {
+					assert.Contains(t, p.Paths, offendingPath, "problem paths must include the offending input's paths")
+				}
+			}
+			assert.True(t, found, "no diagnostics problem of class %q found in %+v", tc.wantClass, doc.Problems)
+		})
+	}
+}
+
+var filestreamAutoIDDisjointCfg = `
+filebeat.inputs:
+  - type: filestream
+    enabled: true
+    paths:
+      - %s
+  - type: filestream
+    enabled: true
+    paths:
+      - %s
+
+filebeat.input_auto_id: generate
+
+path.home: %s
+
+output.file:
+  path: ${path.home}
+  filename: "output-file"
+
+logging:
+  level: debug
+  metrics:
+    enabled: false
+`
+
+func TestFilestreamAutoIDDisjointPathsStart(t *testing.T) {
+	filebeat := integration.NewBeat(
+		t,
+		"filebeat",
+		"../../filebeat.test",
+	)
+	tempDir := filebeat.TempDir()
+
+	firstLog := path.Join(tempDir, "first.log")
+	secondLog := path.Join(tempDir, "second.log")
+	integration.GenerateLogFile(t, firstLog, 5, false)
+	integration.GenerateLogFile(t, secondLog, 5, false)
+
+	filebeat.WriteConfigFile(fmt.Sprintf(filestreamAutoIDDisjointCfg, firstLog, secondLog, tempDir))
+	filebeat.Start()
+
+	filebeat.WaitForLogs(
+		"Input 'filestream' starting",
+		10*time.Second,
+		"Filebeat did not start the generated-id inputs")
+
+	registryFile := filepath.Join(filebeat.TempDir(), "data", "registry", "filebeat", "log.json")
+	filebeat.WaitFileContains(registryFile, `"op":"set"`, 10*time.Second)
+}
+
+var filestreamAutoIDCollidingCfg = `
+filebeat.inputs:
+  - type: filestream
+    enabled: true
+    paths:
+      - %s
+  - type: filestream
+    enabled: true
+    paths:
+      - %s
+
+filebeat.input_auto_id: generate
+
+output.discard.enabled: true
+logging:
+  level: debug
+  metrics:
+    enabled: false
+`
+
+func TestFilestreamAutoIDCollidingPathsStillFail(t *testing.T) {
+	filebeat := integration.NewBeat(
+		t,
+		"filebeat",
+		"../../filebeat.test",
+	)
+
+	// Both inputs cover the exact same paths, so even with auto_id: generate
+	// they resolve to the same id and validation must still reject them.
+	filebeat.WriteConfigFile(fmt.Sprintf(filestreamAutoIDCollidingCfg, "/tmp/same-*.log", "/tmp/same-*.log"))
+	filebeat.Start()
+
+	filebeat.WaitForLogs(
+		"filestream inputs validation error",
+		10*time.Second,
+		"Filebeat did not log a filestream input validation error for colliding generated ids")
+
+	proc, err := filebeat.Process.Wait()
+	require.NoError(t, err, "filebeat process.Wait returned an error")
+	assert.False(t, proc.Success(), "filebeat should have failed to start")
+}
+
+func TestFilestreamAutoIDStableAcrossReorder(t *testing.T) {
+	filebeat := integration.NewBeat(
+		t,
+		"filebeat",
+		"../../filebeat.test",
+	)
+	tempDir := filebeat.TempDir()
+
+	firstLog := path.Join(tempDir, "first.log")
+	secondLog := path.Join(tempDir, "second.log")
+	integration.GenerateLogFile(t, firstLog, 5, false)
+	integration.GenerateLogFile(t, secondLog, 5, false)
+
+	// Start once so both generated ids are persisted to the registry.
+	filebeat.WriteConfigFile(fmt.Sprintf(filestreamAutoIDDisjointCfg, firstLog, secondLog, tempDir))
+	filebeat.Start()
+	filebeat.WaitForLogs(
+		"Input 'filestream' starting",
+		10*time.Second,
+		"Filebeat did not start the generated-id inputs")
+
+	registryFile := filepath.Join(filebeat.TempDir(), "data", "registry", "filebeat", "log.json")
+	filebeat.WaitFileContains(registryFile, `"op":"set"`, 10*time.Second)
+	filebeat.Stop()
+
+	// Re-order the two inputs and restart; the generated ids only depend on
+	// each input's own paths, so they must not change and the files must not
+	// be re-ingested from the start.
+	filebeat.WriteConfigFile(fmt.Sprintf(filestreamAutoIDDisjointCfg, secondLog, firstLog, tempDir))
+	filebeat.Start()
+
+	filebeat.WaitForLogs(
+		"Input 'filestream' starting",
+		10*time.Second,
+		"Filebeat did not restart the generated-id inputs")
+	filebeat.WaitForLogs(
+		fmt.Sprintf("Resuming existing file: %s", firstLog),
+		10*time.Second,
+		"Filebeat did not resume the first file from its existing state after reorder")
+	filebeat.WaitForLogs(
+		fmt.Sprintf("Resuming existing file: %s", secondLog),
+		10*time.Second,
+		"Filebeat did not resume the second file from its existing state after reorder")
+}